@@ -0,0 +1,227 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrQueueFull is returned by FireAsync and FireAsyncContext when the async
+// queue has no room for the event and the Bus's overflow policy is
+// OverflowReturnError.
+var ErrQueueFull = errors.New("event: async queue is full")
+
+// ErrEvicted is the error an AsyncHandle resolves to when its event is
+// evicted from the async queue, before dispatch, to make room for a newer
+// one under OverflowDropOldest.
+var ErrEvicted = errors.New("event: evicted from async queue to make room for a newer event")
+
+// ErrClosed is returned by FireAsync and FireAsyncContext once Close has
+// been called, instead of sending on the (possibly already closed) async
+// queue.
+var ErrClosed = errors.New("event: bus is closed")
+
+const (
+	defaultAsyncWorkers   = 4
+	defaultAsyncQueueSize = 64
+)
+
+// OverflowPolicy controls what FireAsync and FireAsyncContext do when the
+// async queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for space in the queue, honouring context
+	// cancellation if a FireAsyncContext caller supplied one. This is the
+	// default policy.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest evicts the longest-queued event to make room for
+	// the new one. The evicted event's AsyncHandle resolves to ErrEvicted.
+	OverflowDropOldest
+
+	// OverflowDropNewest silently discards the incoming event. The caller
+	// gets no handle and no error.
+	OverflowDropNewest
+
+	// OverflowReturnError rejects the incoming event and reports it to the
+	// caller as ErrQueueFull.
+	OverflowReturnError
+)
+
+// BusOption configures optional behaviour of a Bus created by NewBus.
+type BusOption func(*busConfig)
+
+type busConfig struct {
+	asyncWorkers   int
+	asyncQueueSize int
+	overflow       OverflowPolicy
+	journalPath    string
+}
+
+// WithAsyncDelivery configures the worker pool backing FireAsync: workers
+// goroutines consume events from a queue of size queueSize. If this option
+// is omitted, NewBus still provisions a small default pool so FireAsync is
+// always usable.
+func WithAsyncDelivery(workers, queueSize int) BusOption {
+	return func(c *busConfig) {
+		c.asyncWorkers = workers
+		c.asyncQueueSize = queueSize
+	}
+}
+
+// WithOverflowPolicy sets the policy enacted by FireAsync and
+// FireAsyncContext when the async queue is full. The default is
+// OverflowBlock.
+func WithOverflowPolicy(policy OverflowPolicy) BusOption {
+	return func(c *busConfig) {
+		c.overflow = policy
+	}
+}
+
+// AsyncHandle refers to an event enqueued via FireAsync or
+// FireAsyncContext. It can be waited on to observe the outcome of dispatch,
+// or dropped if the caller isn't interested.
+type AsyncHandle struct {
+	done chan struct{}
+	err  error
+}
+
+func newAsyncHandle() *AsyncHandle {
+	return &AsyncHandle{done: make(chan struct{})}
+}
+
+func (h *AsyncHandle) resolve(err error) {
+	h.err = err
+	close(h.done)
+}
+
+// Wait blocks until the event has been dispatched (or evicted) and returns
+// the resulting error, if any.
+func (h *AsyncHandle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+// Done returns a channel that is closed once the event has been resolved,
+// for use alongside other channels in a select statement.
+func (h *AsyncHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+type asyncJob struct {
+	ctx    context.Context
+	topic  string
+	args   []interface{}
+	handle *AsyncHandle
+}
+
+// FireAsync enqueues topic for asynchronous delivery on the Bus's worker
+// pool and returns, without waiting for dispatch, a handle to the outcome. See
+// FireAsyncContext for the full behaviour.
+func (bus *Bus) FireAsync(topic string, args ...interface{}) (*AsyncHandle, error) {
+	return bus.FireAsyncContext(context.Background(), topic, args...)
+}
+
+// FireAsyncContext behaves like FireAsync, but accepts a context.Context
+// that is forwarded to FireContext once the event is dequeued, and that
+// also bounds how long OverflowBlock waits for queue space. What happens if
+// the queue is full is governed by the Bus's overflow policy, set via
+// WithOverflowPolicy. If Close has been called, FireAsyncContext returns
+// ErrClosed instead of sending on the async queue; a call racing with Close
+// either enqueues its event before the queue is closed or returns ErrClosed.
+func (bus *Bus) FireAsyncContext(ctx context.Context, topic string, args ...interface{}) (*AsyncHandle, error) {
+	bus.closeMu.RLock()
+	defer bus.closeMu.RUnlock()
+
+	if bus.closed {
+		return nil, ErrClosed
+	}
+
+	handle := newAsyncHandle()
+	job := &asyncJob{ctx: ctx, topic: topic, args: args, handle: handle}
+
+	switch bus.overflow {
+	case OverflowDropOldest:
+		for {
+			select {
+			case bus.asyncQueue <- job:
+				atomic.AddInt64(&bus.queueDepth, 1)
+				return handle, nil
+			default:
+			}
+
+			select {
+			case evicted := <-bus.asyncQueue:
+				atomic.AddInt64(&bus.queueDepth, -1)
+				evicted.handle.resolve(ErrEvicted)
+			default:
+			}
+		}
+
+	case OverflowDropNewest:
+		select {
+		case bus.asyncQueue <- job:
+			atomic.AddInt64(&bus.queueDepth, 1)
+			return handle, nil
+		default:
+			return nil, nil
+		}
+
+	case OverflowReturnError:
+		select {
+		case bus.asyncQueue <- job:
+			atomic.AddInt64(&bus.queueDepth, 1)
+			return handle, nil
+		default:
+			return nil, ErrQueueFull
+		}
+
+	default: // OverflowBlock
+		select {
+		case bus.asyncQueue <- job:
+			atomic.AddInt64(&bus.queueDepth, 1)
+			return handle, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// QueueDepth reports the approximate number of events currently queued for
+// asynchronous delivery.
+func (bus *Bus) QueueDepth() int {
+	return int(atomic.LoadInt64(&bus.queueDepth))
+}
+
+// Close stops the Bus's async worker pool once its queue has drained, and
+// closes its journal file, if any. Any events still queued are dispatched
+// as normal before the workers exit. It is safe to call FireAsync or
+// FireAsyncContext concurrently with Close: they hold closeMu for reading
+// around every send, so Close's write lock waits for any in-flight sends to
+// finish before it closes the queue, and once closed is set, later calls
+// see it and return ErrClosed instead of sending, including calls made
+// after Close has returned.
+func (bus *Bus) Close() error {
+	bus.closeMu.Lock()
+	bus.closed = true
+	close(bus.asyncQueue)
+	bus.closeMu.Unlock()
+
+	bus.asyncGroup.Wait()
+
+	if bus.journal != nil {
+		return bus.journal.close()
+	}
+
+	return nil
+}
+
+func (bus *Bus) asyncWorker() {
+	defer bus.asyncGroup.Done()
+
+	for job := range bus.asyncQueue {
+		atomic.AddInt64(&bus.queueDepth, -1)
+		job.handle.resolve(bus.FireContext(job.ctx, job.topic, job.args...))
+	}
+}
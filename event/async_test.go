@@ -0,0 +1,159 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFireAsyncDispatches(t *testing.T) {
+	bus := newTestBus(t, WithAsyncDelivery(1, 4))
+
+	c := make(chan int, 1)
+	_, err := bus.Add("test", func(n int) { c <- n })
+	if err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	handle, err := bus.FireAsync("test", 42)
+	if err != nil {
+		t.Fatalf("Encountered error firing async event: %+v", err)
+	}
+
+	if err := handle.Wait(); err != nil {
+		t.Errorf("Encountered unexpected error waiting for handle: %+v", err)
+	}
+
+	select {
+	case n := <-c:
+		if n != 42 {
+			t.Errorf("Expected callback arg 42, got %d", n)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Callback was not dispatched")
+	}
+}
+
+func TestFireAsyncOverflowReturnError(t *testing.T) {
+	bus := newTestBus(t, WithAsyncDelivery(0, 1), WithOverflowPolicy(OverflowReturnError))
+
+	if _, err := bus.FireAsync("test"); err != nil {
+		t.Fatalf("Encountered error filling queue: %+v", err)
+	}
+
+	if _, err := bus.FireAsync("test"); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %+v", err)
+	}
+}
+
+func TestFireAsyncOverflowDropNewest(t *testing.T) {
+	bus := newTestBus(t, WithAsyncDelivery(0, 1), WithOverflowPolicy(OverflowDropNewest))
+
+	if _, err := bus.FireAsync("test"); err != nil {
+		t.Fatalf("Encountered error filling queue: %+v", err)
+	}
+
+	handle, err := bus.FireAsync("test")
+	if err != nil {
+		t.Errorf("Expected no error dropping newest event, got %+v", err)
+	}
+	if handle != nil {
+		t.Errorf("Expected no handle for dropped event, got %+v", handle)
+	}
+}
+
+func TestFireAsyncOverflowDropOldest(t *testing.T) {
+	bus := newTestBus(t, WithAsyncDelivery(0, 1), WithOverflowPolicy(OverflowDropOldest))
+
+	oldest, err := bus.FireAsync("test")
+	if err != nil {
+		t.Fatalf("Encountered error filling queue: %+v", err)
+	}
+
+	if _, err := bus.FireAsync("test"); err != nil {
+		t.Fatalf("Encountered error enqueuing newer event: %+v", err)
+	}
+
+	if err := oldest.Wait(); !errors.Is(err, ErrEvicted) {
+		t.Errorf("Expected oldest event to be evicted, got %+v", err)
+	}
+}
+
+func TestFireAsyncContextBlockRespectsCancellation(t *testing.T) {
+	bus := newTestBus(t, WithAsyncDelivery(0, 1), WithOverflowPolicy(OverflowBlock))
+
+	if _, err := bus.FireAsync("test"); err != nil {
+		t.Fatalf("Encountered error filling queue: %+v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := bus.FireAsyncContext(ctx, "test"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %+v", err)
+	}
+}
+
+func TestCloseWaitsForQueuedEventsToDispatch(t *testing.T) {
+	bus := newTestBus(t, WithAsyncDelivery(1, 4))
+
+	c := make(chan int, 1)
+	if _, err := bus.Add("test", func(n int) { c <- n }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	if _, err := bus.FireAsync("test", 42); err != nil {
+		t.Fatalf("Encountered error firing async event: %+v", err)
+	}
+
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Encountered error closing bus: %+v", err)
+	}
+
+	select {
+	case n := <-c:
+		if n != 42 {
+			t.Errorf("Expected callback arg 42, got %d", n)
+		}
+	default:
+		t.Errorf("Expected Close to wait for the queued event to dispatch before returning")
+	}
+}
+
+func TestFireAsyncConcurrentWithCloseDoesNotPanic(t *testing.T) {
+	bus := newTestBus(t, WithAsyncDelivery(2, 4))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := bus.FireAsync("test")
+			if err != nil && !errors.Is(err, ErrClosed) {
+				t.Errorf("Expected nil or ErrClosed, got %+v", err)
+			}
+		}()
+	}
+
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Encountered error closing bus: %+v", err)
+	}
+
+	wg.Wait()
+}
+
+func TestQueueDepth(t *testing.T) {
+	bus := newTestBus(t, WithAsyncDelivery(0, 4))
+
+	for i := 0; i < 3; i++ {
+		if _, err := bus.FireAsync("test"); err != nil {
+			t.Fatalf("Encountered error enqueuing event: %+v", err)
+		}
+	}
+
+	if depth := bus.QueueDepth(); depth != 3 {
+		t.Errorf("Expected queue depth 3, got %d", depth)
+	}
+}
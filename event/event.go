@@ -1,52 +1,111 @@
 package event
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
 )
 
 // Bus is used to fire events to various callbacks which subscribe to various
 // topics
 type Bus struct {
 	sync.RWMutex
-	callbacks map[string][]reflect.Value
+	topics     *topicTrie
+	signatures map[string][]reflect.Type
+
+	asyncQueue chan *asyncJob
+	asyncGroup sync.WaitGroup
+	overflow   OverflowPolicy
+	queueDepth int64
+	closeMu    sync.RWMutex
+	closed     bool
+
+	journal *journal
+	seqMu   sync.Mutex
+	seq     map[string]uint64
 }
 
-// NewBus creates a new Bus, ready to accept and process fired events
-func NewBus() *Bus {
-	bus := Bus{
-		callbacks: make(map[string][]reflect.Value),
+// NewBus creates a new Bus, ready to accept and process fired events. By
+// default its async worker pool is sized for light use; pass
+// WithAsyncDelivery to tune it, and WithOverflowPolicy to change what
+// happens when the queue fills up. If WithJournal was given and the
+// journal file can't be opened, NewBus returns that error rather than
+// constructing a Bus: a bad journal path is an ordinary, recoverable
+// configuration error, not a programmer error.
+func NewBus(opts ...BusOption) (*Bus, error) {
+	cfg := busConfig{
+		asyncWorkers:   defaultAsyncWorkers,
+		asyncQueueSize: defaultAsyncQueueSize,
+		overflow:       OverflowBlock,
 	}
 
-	return &bus
-}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
-// Add registers a callback with the Bus to receive events of the provided
-// topic
-func (bus *Bus) Add(t string, f interface{}) error {
-	err := validateCallback(f)
-	if err != nil {
-		return err
+	bus := &Bus{
+		topics:     newTopicTrie(),
+		signatures: make(map[string][]reflect.Type),
+		asyncQueue: make(chan *asyncJob, cfg.asyncQueueSize),
+		overflow:   cfg.overflow,
+		seq:        make(map[string]uint64),
 	}
 
-	bus.Lock()
-	defer bus.Unlock()
+	if cfg.journalPath != "" {
+		j, err := openJournal(cfg.journalPath)
+		if err != nil {
+			return nil, err
+		}
+		bus.journal = j
+	}
 
-	callbacks, ok := bus.callbacks[t]
-	if !ok {
-		callbacks = make([]reflect.Value, 0)
+	bus.asyncGroup.Add(cfg.asyncWorkers)
+	for i := 0; i < cfg.asyncWorkers; i++ {
+		go bus.asyncWorker()
 	}
 
-	bus.callbacks[t] = append(callbacks, reflect.ValueOf(f))
+	return bus, nil
+}
 
-	return nil
+// RegisterTopic declares the argument types that callbacks subscribing to
+// topic must accept. Once declared, Add rejects callbacks whose arity or
+// parameter types don't match, and Fire validates its arguments against the
+// signature before dispatching to any callback. Callbacks already added to
+// topic before RegisterTopic is called are not retroactively checked.
+func (bus *Bus) RegisterTopic(topic string, argTypes ...reflect.Type) {
+	bus.Lock()
+	defer bus.Unlock()
+
+	bus.signatures[topic] = argTypes
+}
+
+// Add registers a callback with the Bus to receive events of the provided
+// topic, and returns a Subscription identifying that registration. Topic is
+// a dotted pattern such as "irc.privmsg.#channel"; a segment of "+" matches
+// exactly one concrete segment at Fire time, and a trailing "#" matches any
+// number of them. If the topic has a signature declared via RegisterTopic,
+// f is rejected with a descriptive error unless its parameters match that
+// signature.
+func (bus *Bus) Add(t string, f interface{}) (*Subscription, error) {
+	return bus.addSubscription(t, f, func(*subscriptionEntry) {})
 }
 
 // Delete removes the first encountered instance of a callback from the
 // provided topic. This method will return true if the provided callback was
 // found and deleted, false otherwise. An error will be returned when the
 // provided callback is invalid.
+//
+// Delete identifies the callback by reflect.Value equality, which can't
+// distinguish two closures produced by the same factory; prefer the
+// Subscription returned by Add when that matters.
 func (bus *Bus) Delete(topic string, callback interface{}) (bool, error) {
 	bus.Lock()
 	defer bus.Unlock()
@@ -63,13 +122,61 @@ func (bus *Bus) DeleteAll(topic string, callback interface{}) (bool, error) {
 
 // Fire will call all registered callbacks for the provided topic with the
 // provided arguments. A call to this method will block until all registered
-// callbacks have returned.
+// callbacks have returned. It is a no-error wrapper around FireContext using
+// context.Background(); any errors returned by callbacks are discarded. Use
+// FireContext directly to observe them or to bound dispatch with a context.
+//
+// If the topic has a signature declared via RegisterTopic, args are
+// validated against it before any callback is invoked; a mismatch produces
+// a descriptive error that, since Fire discards FireContext's return
+// value, is silently dropped. Use FireContext to observe it.
 func (bus *Bus) Fire(t string, args ...interface{}) {
+	_ = bus.FireContext(context.Background(), t, args...)
+}
+
+// FireContext behaves like Fire, but accepts a context.Context that bounds
+// dispatch: once ctx is cancelled, no further callbacks for this event are
+// started, though ones already dispatched are still allowed to finish.
+// Callbacks may optionally take ctx as their first parameter, and may
+// optionally return a single error; FireContext joins every such error,
+// along with ctx.Err() if ctx is done once the dispatched callbacks have
+// returned, via errors.Join.
+//
+// t is a concrete, wildcard-free topic; every pattern registered via Add
+// that matches it, including ones using "+" and "#", receives the event.
+// A subscription added via AddWithFilter only receives it if its predicate
+// returns true for args; one added via AddOnce is unsubscribed right after
+// this dispatch; one added via AddWithTTL that has outlived its TTL is
+// unsubscribed without being dispatched to at all.
+//
+// Every call whose args pass signature validation assigns t a sequence
+// number, one higher than its last, visible
+// via Sequence. If the Bus was configured with WithJournal, the event is
+// also appended there before dispatch, so AddFromOffset can replay it to
+// subscribers that join later.
+func (bus *Bus) FireContext(ctx context.Context, t string, args ...interface{}) error {
 	bus.RLock()
-	defer bus.RUnlock()
 
-	if _, ok := bus.callbacks[t]; !ok {
-		return
+	if sig, ok := bus.signatures[t]; ok {
+		if err := validateArgs(sig, args); err != nil {
+			bus.RUnlock()
+			return fmt.Errorf("event: FireContext(%q): %w", t, err)
+		}
+	}
+
+	var errs []error
+
+	seq := bus.nextSequence(t)
+	if bus.journal != nil {
+		if err := bus.journal.append(journalRecord{Topic: t, Seq: seq, Args: args}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	entries := bus.topics.match(t)
+	if len(entries) == 0 {
+		bus.RUnlock()
+		return errors.Join(errs...)
 	}
 
 	argVals := make([]reflect.Value, len(args))
@@ -78,35 +185,114 @@ func (bus *Bus) Fire(t string, args ...interface{}) {
 		argVals[i] = reflect.ValueOf(args[i])
 	}
 
-	var wg sync.WaitGroup
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		remove []uint64
+	)
+
+	now := time.Now()
+
+dispatch:
+	for _, e := range entries {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		default:
+		}
+
+		if e.pending {
+			continue
+		}
+
+		if e.expired(now) {
+			remove = append(remove, e.id)
+			continue
+		}
+
+		if e.filter != nil && !e.filter(args...) {
+			continue
+		}
+
+		if e.once {
+			remove = append(remove, e.id)
+		}
 
-	for _, c := range bus.callbacks[t] {
 		wg.Add(1)
-		go func(c reflect.Value) {
+		go func(e *subscriptionEntry) {
 			defer wg.Done()
-			c.Call(argVals)
-		}(c)
+
+			ct := e.fn.Type()
+
+			callArgs := argVals
+			if callbackWantsContext(ct) {
+				callArgs = append([]reflect.Value{reflect.ValueOf(ctx)}, argVals...)
+			}
+
+			results := e.fn.Call(callArgs)
+
+			if callbackReturnsError(ct) {
+				if err, _ := results[0].Interface().(error); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}(e)
 	}
 
 	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	bus.RUnlock()
+
+	// Entries matched via "+" or "#" are registered under their pattern, not
+	// under t, so they are looked up by id across the whole trie.
+	if len(remove) > 0 {
+		bus.Lock()
+		bus.topics.walk(func(n *topicNode) {
+			for _, id := range remove {
+				if i := n.byID(id); i >= 0 {
+					n.callbacks = append(n.callbacks[:i], n.callbacks[i+1:]...)
+				}
+			}
+		})
+		bus.topics.pruneEmpty()
+		bus.Unlock()
+	}
+
+	return errors.Join(errs...)
 }
 
-// Purge will remove all instances of the provided callback from all existing
-// topics. See Delete() for return values.
+// Purge will remove all instances of the provided callback from every
+// registered topic pattern. See Delete() for return values.
 func (bus *Bus) Purge(callback interface{}) (bool, error) {
+	err := validateCallback(callback)
+	if err != nil {
+		return false, err
+	}
+
 	bus.Lock()
 	defer bus.Unlock()
 
+	v := reflect.ValueOf(callback)
 	found := false
-	for topic := range bus.callbacks {
-		del, err := bus.del(topic, callback, true)
-		if del {
-			found = true
-		}
-		if err != nil {
-			return found, err
+
+	bus.topics.walk(func(n *topicNode) {
+		for i := 0; i < len(n.callbacks); i++ {
+			if n.callbacks[i].fn == v {
+				n.callbacks = append(n.callbacks[:i], n.callbacks[i+1:]...)
+				found = true
+				i--
+			}
 		}
-	}
+	})
+
+	bus.topics.pruneEmpty()
+
 	return found, nil
 }
 
@@ -114,7 +300,7 @@ func (bus *Bus) Purge(callback interface{}) (bool, error) {
 func (bus *Bus) Reset() {
 	bus.Lock()
 	defer bus.Unlock()
-	bus.callbacks = make(map[string][]reflect.Value)
+	bus.topics = newTopicTrie()
 }
 
 func (bus *Bus) del(t string, f interface{}, all bool) (bool, error) {
@@ -123,7 +309,8 @@ func (bus *Bus) del(t string, f interface{}, all bool) (bool, error) {
 		return false, err
 	}
 
-	if _, ok := bus.callbacks[t]; !ok {
+	node := bus.topics.node(t)
+	if node == nil {
 		return false, nil
 	}
 
@@ -131,27 +318,98 @@ func (bus *Bus) del(t string, f interface{}, all bool) (bool, error) {
 
 	found := false
 
-	for i := 0; i < len(bus.callbacks[t]); i++ {
-		if bus.callbacks[t][i] == v {
-			bus.callbacks[t] = append(bus.callbacks[t][:i], bus.callbacks[t][i+1:]...)
+	for i := 0; i < len(node.callbacks); i++ {
+		if node.callbacks[i].fn == v {
+			node.callbacks = append(node.callbacks[:i], node.callbacks[i+1:]...)
 			found = true
+			i--
 			if !all {
 				break
 			}
 		}
 	}
 
-	if len(bus.callbacks[t]) == 0 {
-		delete(bus.callbacks, t)
-	}
+	bus.topics.pruneEmpty()
 
 	return found, nil
 }
 
 func validateCallback(f interface{}) error {
-	if reflect.TypeOf(f).Kind() != reflect.Func {
+	ft := reflect.TypeOf(f)
+
+	if ft.Kind() != reflect.Func {
 		return fmt.Errorf("Provided callback is not a func")
 	}
 
+	if ft.NumOut() > 1 || (ft.NumOut() == 1 && !ft.Out(0).Implements(errorType)) {
+		return fmt.Errorf("callback must return nothing or a single error")
+	}
+
+	return nil
+}
+
+// callbackWantsContext reports whether ft's first parameter is a
+// context.Context, which Fire and FireContext pass through when dispatching.
+func callbackWantsContext(ft reflect.Type) bool {
+	return ft.NumIn() > 0 && ft.In(0) == contextType
+}
+
+// callbackReturnsError reports whether ft has a single error return value.
+func callbackReturnsError(ft reflect.Type) bool {
+	return ft.NumOut() == 1
+}
+
+// validateCallbackSignature checks that ft, the type of a callback being
+// added to a topic, can accept sig, the declared argument types for that
+// topic. An optional leading context.Context parameter is ignored.
+func validateCallbackSignature(ft reflect.Type, sig []reflect.Type) error {
+	skip := 0
+	if callbackWantsContext(ft) {
+		skip = 1
+	}
+
+	numIn := ft.NumIn() - skip
+
+	if !ft.IsVariadic() && numIn != len(sig) {
+		return fmt.Errorf("expected %d parameters, got %d", len(sig), numIn)
+	}
+
+	for i, want := range sig {
+		var got reflect.Type
+		switch {
+		case ft.IsVariadic() && i >= numIn-1:
+			got = ft.In(ft.NumIn() - 1).Elem()
+		case i < numIn:
+			got = ft.In(i + skip)
+		default:
+			return fmt.Errorf("expected %d parameters, got %d", len(sig), numIn)
+		}
+
+		if !want.AssignableTo(got) {
+			return fmt.Errorf("parameter %d: %s is not assignable to %s", i, want, got)
+		}
+	}
+
+	return nil
+}
+
+// validateArgs checks that args, the arguments passed to Fire, match sig,
+// the declared argument types for the topic being fired.
+func validateArgs(sig []reflect.Type, args []interface{}) error {
+	if len(args) != len(sig) {
+		return fmt.Errorf("expected %d arguments, got %d", len(sig), len(args))
+	}
+
+	for i, want := range sig {
+		if args[i] == nil {
+			continue
+		}
+
+		got := reflect.TypeOf(args[i])
+		if !got.AssignableTo(want) {
+			return fmt.Errorf("argument %d: %s is not assignable to %s", i, got, want)
+		}
+	}
+
 	return nil
 }
@@ -1,8 +1,11 @@
 package event
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -19,6 +22,21 @@ func testCallbackEmptyTwo() {
 	return
 }
 
+// newTestBus creates a Bus via NewBus, failing the test immediately if
+// opts describe a configuration NewBus rejects (e.g. an unopenable
+// journal path), so callers that don't care about that error don't have
+// to thread it through every test.
+func newTestBus(t *testing.T, opts ...BusOption) *Bus {
+	t.Helper()
+
+	bus, err := NewBus(opts...)
+	if err != nil {
+		t.Fatalf("Encountered error creating bus: %+v", err)
+	}
+
+	return bus
+}
+
 func testNewAckCallback(c chan<- []reflect.Value) func(...interface{}) {
 	return func(args ...interface{}) {
 		argVals := make([]reflect.Value, len(args))
@@ -31,22 +49,22 @@ func testNewAckCallback(c chan<- []reflect.Value) func(...interface{}) {
 }
 
 func TestAddInvalid(t *testing.T) {
-	bus := NewBus()
+	bus := newTestBus(t)
 
-	err := bus.Add("test", "invalid")
+	_, err := bus.Add("test", "invalid")
 	if err == nil {
 		t.Errorf("Did not get error adding invalid callback")
 	}
 }
 
 func TestAdd(t *testing.T) {
-	bus := NewBus()
+	bus := newTestBus(t)
 
 	topics := make([]string, testNumTopics)
 	for i := 0; i < len(topics); i++ {
 		topics[i] = strconv.Itoa(i)
 		for j := 0; j < testNumCallbacks; j++ {
-			err := bus.Add(topics[i], testCallbackEmpty)
+			_, err := bus.Add(topics[i], testCallbackEmpty)
 			if err != nil {
 				t.Errorf("Encountered error adding good callback: %+v", err)
 			}
@@ -56,25 +74,25 @@ func TestAdd(t *testing.T) {
 	v := reflect.ValueOf(testCallbackEmpty)
 
 	for _, topic := range topics {
-		callbacks, ok := bus.callbacks[topic]
-		if !ok {
-			t.Errorf("Topic %s not initialised")
+		node := bus.topics.node(topic)
+		if node == nil {
+			t.Errorf("Topic %s not initialised", topic)
 		}
 
-		if len(callbacks) != testNumCallbacks {
-			t.Errorf("Expected %d callbacks, got %d", testNumCallbacks, len(callbacks))
+		if len(node.callbacks) != testNumCallbacks {
+			t.Errorf("Expected %d callbacks, got %d", testNumCallbacks, len(node.callbacks))
 		}
 
-		for _, callback := range callbacks {
-			if callback != v {
-				t.Errorf("Expected callback %+v, got %+v", v, callback)
+		for _, callback := range node.callbacks {
+			if callback.fn != v {
+				t.Errorf("Expected callback %+v, got %+v", v, callback.fn)
 			}
 		}
 	}
 }
 
 func TestDelete(t *testing.T) {
-	bus := NewBus()
+	bus := newTestBus(t)
 
 	topics := make([]string, testNumTopics)
 	for i := 0; i < len(topics); i++ {
@@ -87,7 +105,7 @@ func TestDelete(t *testing.T) {
 				callback = testCallbackEmptyTwo
 			}
 
-			err := bus.Add(topics[i], callback)
+			_, err := bus.Add(topics[i], callback)
 			if err != nil {
 				t.Errorf("Encountered error adding good callback: %+v", err)
 			}
@@ -119,16 +137,139 @@ func TestDelete(t *testing.T) {
 			t.Errorf("Error deleting callback for topic %s: %+v", topic, err)
 		}
 
-		for i, callback := range bus.callbacks[topic] {
-			if callback != v {
+		for i, callback := range bus.topics.node(topic).callbacks {
+			if callback.fn != v {
 				t.Errorf("Wrong callback found for topic %s at index %d", topic, i)
 			}
 		}
 	}
 }
 
+func TestRegisterTopicAddMismatch(t *testing.T) {
+	bus := newTestBus(t)
+	bus.RegisterTopic("test", reflect.TypeOf(0), reflect.TypeOf(""))
+
+	_, err := bus.Add("test", func(n int) {})
+	if err == nil {
+		t.Errorf("Did not get error adding callback with wrong arity")
+	}
+
+	_, err = bus.Add("test", func(n int, s bool) {})
+	if err == nil {
+		t.Errorf("Did not get error adding callback with wrong parameter type")
+	}
+}
+
+func TestRegisterTopicAddMatch(t *testing.T) {
+	bus := newTestBus(t)
+	bus.RegisterTopic("test", reflect.TypeOf(0), reflect.TypeOf(""))
+
+	_, err := bus.Add("test", func(n int, s string) {})
+	if err != nil {
+		t.Errorf("Encountered error adding matching callback: %+v", err)
+	}
+
+	_, err = bus.Add("test", func(args ...interface{}) {})
+	if err != nil {
+		t.Errorf("Encountered error adding matching variadic callback: %+v", err)
+	}
+}
+
+func TestFireContextMismatchedArgsReturnsError(t *testing.T) {
+	bus := newTestBus(t)
+	bus.RegisterTopic("test", reflect.TypeOf(0))
+
+	_, err := bus.Add("test", func(n int) {})
+	if err != nil {
+		t.Errorf("Encountered error adding matching callback: %+v", err)
+	}
+
+	if err := bus.FireContext(context.Background(), "test", "not an int"); err == nil {
+		t.Errorf("Did not get error firing mismatched args")
+	}
+}
+
+func TestFireContextJoinsErrors(t *testing.T) {
+	bus := newTestBus(t)
+
+	errOne := errors.New("one")
+	errTwo := errors.New("two")
+
+	if _, err := bus.Add("test", func() error { return errOne }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+	if _, err := bus.Add("test", func() error { return errTwo }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+	if _, err := bus.Add("test", func() error { return nil }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	err := bus.FireContext(context.Background(), "test")
+	if err == nil {
+		t.Fatalf("Did not get joined error from failing callbacks")
+	}
+
+	if !errors.Is(err, errOne) {
+		t.Errorf("Joined error does not contain %+v", errOne)
+	}
+	if !errors.Is(err, errTwo) {
+		t.Errorf("Joined error does not contain %+v", errTwo)
+	}
+}
+
+func TestFireContextPassesContext(t *testing.T) {
+	bus := newTestBus(t)
+
+	type ctxKey struct{}
+	want := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	var got interface{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	_, err := bus.Add("test", func(ctx context.Context) {
+		defer wg.Done()
+		got = ctx.Value(ctxKey{})
+	})
+	if err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	if err := bus.FireContext(want, "test"); err != nil {
+		t.Errorf("Encountered unexpected error: %+v", err)
+	}
+
+	wg.Wait()
+
+	if got != "value" {
+		t.Errorf("Callback did not receive expected context value, got %+v", got)
+	}
+}
+
+func TestFireContextCancelledAbortsRemaining(t *testing.T) {
+	bus := newTestBus(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	if _, err := bus.Add("test", func() { called = true }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	err := bus.FireContext(ctx, "test")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error to contain context.Canceled, got %+v", err)
+	}
+
+	if called {
+		t.Errorf("Callback was dispatched after context was already cancelled")
+	}
+}
+
 func TestFire(t *testing.T) {
-	bus := NewBus()
+	bus := newTestBus(t)
 	chans := make([]chan []reflect.Value, testNumCallbacks)
 
 	for i := range chans {
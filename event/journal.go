@@ -0,0 +1,278 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// journalRecord is a single entry appended to a Bus's journal file.
+type journalRecord struct {
+	Topic string
+	Seq   uint64
+	Args  []interface{}
+}
+
+// journal persists every fired event to an append-only, gob-encoded file so
+// it can be replayed to late-joining subscribers via AddFromOffset, or
+// recovered after a crash. Concrete types passed as event args must be
+// registered with gob.Register before being fired, same as any other gob
+// payload.
+type journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	enc  *gob.Encoder
+}
+
+func openJournal(path string) (*journal, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("event: opening journal %s: %w", path, err)
+	}
+
+	return &journal{
+		path: path,
+		file: f,
+		enc:  gob.NewEncoder(f),
+	}, nil
+}
+
+func (j *journal) append(rec journalRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.enc.Encode(rec); err != nil {
+		return fmt.Errorf("event: appending to journal: %w", err)
+	}
+
+	return nil
+}
+
+// records returns, in order, every journalled record on topic whose
+// sequence number is greater than offset.
+func (j *journal) records(topic string, offset uint64) ([]journalRecord, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		return nil, fmt.Errorf("event: reading journal: %w", err)
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+
+	var recs []journalRecord
+
+	for {
+		var rec journalRecord
+
+		if err := dec.Decode(&rec); err != nil {
+			if err == io.EOF {
+				return recs, nil
+			}
+			return nil, fmt.Errorf("event: decoding journal record: %w", err)
+		}
+
+		if rec.Topic != topic || rec.Seq <= offset {
+			continue
+		}
+
+		recs = append(recs, rec)
+	}
+}
+
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// WithJournal enables a persistent journal of every fired event, appended to
+// the file at path. AddFromOffset replays history from this file before
+// handing a new subscriber off to the live stream.
+func WithJournal(path string) BusOption {
+	return func(c *busConfig) {
+		c.journalPath = path
+	}
+}
+
+// Sequence returns the most recent sequence number Fire or FireContext
+// assigned to topic, or 0 if it has never been fired.
+func (bus *Bus) Sequence(topic string) uint64 {
+	bus.seqMu.Lock()
+	defer bus.seqMu.Unlock()
+	return bus.seq[topic]
+}
+
+func (bus *Bus) nextSequence(topic string) uint64 {
+	bus.seqMu.Lock()
+	defer bus.seqMu.Unlock()
+	bus.seq[topic]++
+	return bus.seq[topic]
+}
+
+// maxCatchUpPasses bounds the catch-up loop in AddFromOffset. Each pass
+// that finds new records means the topic was fired again while the
+// previous pass's callbacks were running; sustained, continuous firing on
+// the topic could in principle keep that up forever, so instead of
+// spinning indefinitely AddFromOffset gives up and reports an error after
+// this many passes.
+const maxCatchUpPasses = 1000
+
+// AddFromOffset registers cb for topic like Add, but first replays every
+// journalled event for topic with a sequence number greater than offset,
+// synchronously and in the order it was originally fired, before cb starts
+// receiving live events. Replay is a no-op if the Bus was not configured
+// with WithJournal.
+//
+// cb is registered as pending before any replay runs, so Fire/FireContext
+// can append to the journal and dispatch to other subscribers throughout,
+// but skip cb entirely. Catching up is a loop: read the records since the
+// last one replayed, invoke cb for them with no lock held (so a cb that
+// calls back into the Bus doesn't deadlock against AddFromOffset's own
+// write lock), then check again. Once a pass finds nothing new, cb is
+// flipped live and starts receiving events through the normal dispatch
+// path instead. If cb returns an error, or a pass fails to read the
+// journal, the subscription is torn down before the error is returned, so
+// callers never have to distinguish a live Subscription from a
+// permanently-pending one.
+func (bus *Bus) AddFromOffset(topic string, offset uint64, cb interface{}) (*Subscription, error) {
+	err := validateCallback(cb)
+	if err != nil {
+		return nil, err
+	}
+
+	if bus.journal == nil {
+		return bus.Add(topic, cb)
+	}
+
+	v := reflect.ValueOf(cb)
+	last := offset
+
+	var sub *Subscription
+
+	abort := func(err error) (*Subscription, error) {
+		if sub != nil {
+			bus.removeSubscription(topic, sub.id)
+		}
+		return nil, err
+	}
+
+	for pass := 0; ; pass++ {
+		if pass >= maxCatchUpPasses {
+			return abort(fmt.Errorf("event: AddFromOffset(%q): catch-up did not converge after %d passes", topic, maxCatchUpPasses))
+		}
+
+		recs, caughtUp, err := bus.catchUpPass(topic, last, cb, &sub)
+		if err != nil {
+			return abort(err)
+		}
+
+		for _, rec := range recs {
+			if err := callCallback(v, rec.Args); err != nil {
+				return abort(err)
+			}
+			last = rec.Seq
+		}
+
+		if caughtUp {
+			return sub, nil
+		}
+	}
+}
+
+// catchUpPass runs one iteration of AddFromOffset's catch-up loop under the
+// Bus's write lock: it reads the records on topic since last, registering
+// sub as a pending subscription on the first call, and marks it live if the
+// read comes back empty. *sub is assigned by the first call and read by
+// later ones, so every pass works on the subscription the first registered.
+func (bus *Bus) catchUpPass(topic string, last uint64, cb interface{}, sub **Subscription) ([]journalRecord, bool, error) {
+	bus.Lock()
+	defer bus.Unlock()
+
+	recs, err := bus.journal.records(topic, last)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if *sub == nil {
+		*sub, err = bus.addSubscriptionLocked(topic, cb, func(e *subscriptionEntry) { e.pending = true })
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if len(recs) == 0 {
+		bus.setPendingLocked(topic, (*sub).id, false)
+		return recs, true, nil
+	}
+
+	return recs, false, nil
+}
+
+// callCallback invokes c with args, passing context.Background() if c wants
+// a leading context.Context, and reports any error c returns.
+func callCallback(c reflect.Value, args []interface{}) error {
+	argVals := make([]reflect.Value, len(args))
+	for i := range args {
+		argVals[i] = reflect.ValueOf(args[i])
+	}
+
+	ct := c.Type()
+	if callbackWantsContext(ct) {
+		argVals = append([]reflect.Value{reflect.ValueOf(context.Background())}, argVals...)
+	}
+
+	results := c.Call(argVals)
+
+	if callbackReturnsError(ct) {
+		if err, _ := results[0].Interface().(error); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// busSnapshot is the serializable form of a Bus's sequence counters.
+type busSnapshot struct {
+	Seq map[string]uint64
+}
+
+// Snapshot captures the Bus's current per-topic sequence counters, so they
+// can be restored after a restart without replaying the whole journal just
+// to recompute them.
+func (bus *Bus) Snapshot() ([]byte, error) {
+	bus.seqMu.Lock()
+	seq := make(map[string]uint64, len(bus.seq))
+	for topic, n := range bus.seq {
+		seq[topic] = n
+	}
+	bus.seqMu.Unlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(busSnapshot{Seq: seq}); err != nil {
+		return nil, fmt.Errorf("event: encoding snapshot: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Restore loads sequence counters previously captured by Snapshot. It's
+// meant to be called once, right after NewBus, before any events are fired.
+func (bus *Bus) Restore(data []byte) error {
+	var snap busSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snap); err != nil {
+		return fmt.Errorf("event: decoding snapshot: %w", err)
+	}
+
+	bus.seqMu.Lock()
+	defer bus.seqMu.Unlock()
+	bus.seq = snap.Seq
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package event
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewBusReturnsErrorForUnopenableJournal(t *testing.T) {
+	if _, err := NewBus(WithJournal(t.TempDir())); err == nil {
+		t.Fatalf("Expected error opening a journal path that is a directory, got nil")
+	}
+}
+
+func TestSequenceIncrementsPerTopic(t *testing.T) {
+	bus := newTestBus(t)
+
+	if bus.Sequence("test") != 0 {
+		t.Errorf("Expected sequence 0 before any events fired")
+	}
+
+	bus.Fire("test")
+	bus.Fire("test")
+	bus.Fire("other")
+
+	if seq := bus.Sequence("test"); seq != 2 {
+		t.Errorf("Expected sequence 2 for topic test, got %d", seq)
+	}
+	if seq := bus.Sequence("other"); seq != 1 {
+		t.Errorf("Expected sequence 1 for topic other, got %d", seq)
+	}
+}
+
+func TestAddFromOffsetReplaysJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	bus := newTestBus(t, WithJournal(path))
+
+	bus.Fire("test", 1)
+	bus.Fire("test", 2)
+	bus.Fire("test", 3)
+
+	var replayed []int
+	_, err := bus.AddFromOffset("test", 1, func(n int) { replayed = append(replayed, n) })
+	if err != nil {
+		t.Fatalf("Encountered error adding from offset: %+v", err)
+	}
+
+	if len(replayed) != 2 || replayed[0] != 2 || replayed[1] != 3 {
+		t.Errorf("Expected replay of [2 3], got %v", replayed)
+	}
+
+	replayed = nil
+	bus.Fire("test", 4)
+
+	if len(replayed) != 1 || replayed[0] != 4 {
+		t.Errorf("Expected live event [4] after replay, got %v", replayed)
+	}
+}
+
+func TestAddFromOffsetWithoutJournalBehavesLikeAdd(t *testing.T) {
+	bus := newTestBus(t)
+
+	var got int
+	_, err := bus.AddFromOffset("test", 0, func(n int) { got = n })
+	if err != nil {
+		t.Fatalf("Encountered error adding from offset: %+v", err)
+	}
+
+	bus.Fire("test", 5)
+
+	if got != 5 {
+		t.Errorf("Expected live event to reach callback, got %d", got)
+	}
+}
+
+func TestCloseClosesJournalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	bus := newTestBus(t, WithJournal(path))
+
+	bus.Fire("test", 1)
+
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Encountered error closing bus: %+v", err)
+	}
+
+	if err := bus.journal.append(journalRecord{Topic: "test", Seq: 2}); err == nil {
+		t.Errorf("Expected append to a closed journal file to fail")
+	}
+}
+
+func TestAddFromOffsetRegistersBeforeReleasingLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	bus := newTestBus(t, WithJournal(path))
+
+	bus.Fire("test", 1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bus.Fire("test", 2)
+	}()
+
+	var replayed []int
+	sub, err := bus.AddFromOffset("test", 0, func(n int) { replayed = append(replayed, n) })
+	if err != nil {
+		t.Fatalf("Encountered error adding from offset: %+v", err)
+	}
+	if sub == nil {
+		t.Fatalf("Expected a non-nil subscription")
+	}
+
+	<-done
+
+	if len(replayed) == 0 {
+		t.Errorf("Expected at least the replayed event to be observed")
+	}
+}
+
+func TestAddFromOffsetCallbackCanFireWithoutDeadlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	bus := newTestBus(t, WithJournal(path))
+
+	bus.Fire("source", 1)
+
+	relayed := make(chan int, 1)
+	if _, err := bus.Add("relay", func(n int) { relayed <- n }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := bus.AddFromOffset("source", 0, func(n int) { bus.Fire("relay", n) })
+		if err != nil {
+			t.Errorf("Encountered error adding from offset: %+v", err)
+		}
+	}()
+
+	select {
+	case n := <-relayed:
+		if n != 1 {
+			t.Errorf("Expected relayed value 1, got %d", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for replay callback's own Fire to be dispatched; likely deadlocked")
+	}
+
+	<-done
+}
+
+func TestAddFromOffsetCallbackErrorLeavesNoSubscription(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	bus := newTestBus(t, WithJournal(path))
+
+	bus.Fire("test", 1)
+
+	wantErr := errors.New("replay failed")
+	sub, err := bus.AddFromOffset("test", 0, func(n int) error { return wantErr })
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Expected callback error to propagate, got %+v", err)
+	}
+	if sub != nil {
+		t.Fatalf("Expected no subscription after a failed replay, got %+v", sub)
+	}
+
+	node := bus.topics.node("test")
+	if node != nil && len(node.callbacks) != 0 {
+		t.Errorf("Expected no dangling subscription left registered, found %d", len(node.callbacks))
+	}
+}
+
+func TestSnapshotRestoreRoundTrips(t *testing.T) {
+	bus := newTestBus(t)
+	bus.Fire("test")
+	bus.Fire("test")
+	bus.Fire("other")
+
+	data, err := bus.Snapshot()
+	if err != nil {
+		t.Fatalf("Encountered error snapshotting: %+v", err)
+	}
+
+	restored := newTestBus(t)
+	if err := restored.Restore(data); err != nil {
+		t.Fatalf("Encountered error restoring: %+v", err)
+	}
+
+	if seq := restored.Sequence("test"); seq != 2 {
+		t.Errorf("Expected restored sequence 2 for topic test, got %d", seq)
+	}
+	if seq := restored.Sequence("other"); seq != 1 {
+		t.Errorf("Expected restored sequence 1 for topic other, got %d", seq)
+	}
+
+	restored.Fire("test")
+	if seq := restored.Sequence("test"); seq != 3 {
+		t.Errorf("Expected sequence to continue from restored value, got %d", seq)
+	}
+}
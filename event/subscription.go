@@ -0,0 +1,150 @@
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// subscriptionEntry is one callback registered with the Bus, together with
+// the optional once/filter/TTL behaviour governing whether Fire invokes it.
+type subscriptionEntry struct {
+	id        uint64
+	fn        reflect.Value
+	once      bool
+	filter    func(...interface{}) bool
+	expiresAt time.Time
+
+	// pending is true for an entry that AddFromOffset has registered but
+	// not yet caught up on replay; FireContext skips it entirely, as if
+	// it weren't subscribed yet, so replay and live delivery never race
+	// on the same callback.
+	pending bool
+}
+
+var nextSubscriptionID uint64
+
+func newSubscriptionEntry(fn reflect.Value) *subscriptionEntry {
+	return &subscriptionEntry{
+		id: atomic.AddUint64(&nextSubscriptionID, 1),
+		fn: fn,
+	}
+}
+
+func (e *subscriptionEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// Subscription identifies a single callback registered with a Bus via Add,
+// AddOnce, AddWithFilter, or AddWithTTL. Unlike the callback value itself,
+// a Subscription reliably identifies that one registration even when the
+// callback is a closure indistinguishable, by reflect.Value equality, from
+// others produced by the same factory — something Delete and DeleteAll
+// cannot do.
+type Subscription struct {
+	bus   *Bus
+	topic string
+	id    uint64
+}
+
+// Unsubscribe removes this subscription's callback from the Bus. It
+// reports whether the callback was still registered: false means it was
+// already gone, having been removed by an earlier Unsubscribe, by expiring
+// under AddWithTTL, or by firing once under AddOnce.
+func (s *Subscription) Unsubscribe() bool {
+	return s.bus.removeSubscription(s.topic, s.id)
+}
+
+// AddOnce registers a callback with the Bus like Add, but automatically
+// unsubscribes it the first time it is dispatched.
+func (bus *Bus) AddOnce(t string, f interface{}) (*Subscription, error) {
+	return bus.addSubscription(t, f, func(e *subscriptionEntry) { e.once = true })
+}
+
+// AddWithFilter registers a callback with the Bus like Add, but only
+// dispatches to it for events whose arguments satisfy predicate; predicate
+// is consulted fresh before every Fire.
+func (bus *Bus) AddWithFilter(t string, predicate func(...interface{}) bool, f interface{}) (*Subscription, error) {
+	return bus.addSubscription(t, f, func(e *subscriptionEntry) { e.filter = predicate })
+}
+
+// AddWithTTL registers a callback with the Bus like Add, but it stops
+// receiving events, and is unsubscribed, once d has elapsed since
+// registration. Expiry is detected lazily, the next time the topic fires,
+// rather than by a background timer.
+func (bus *Bus) AddWithTTL(t string, d time.Duration, f interface{}) (*Subscription, error) {
+	return bus.addSubscription(t, f, func(e *subscriptionEntry) { e.expiresAt = time.Now().Add(d) })
+}
+
+func (bus *Bus) addSubscription(t string, f interface{}, configure func(*subscriptionEntry)) (*Subscription, error) {
+	err := validateCallback(f)
+	if err != nil {
+		return nil, err
+	}
+
+	bus.Lock()
+	defer bus.Unlock()
+
+	return bus.addSubscriptionLocked(t, f, configure)
+}
+
+// addSubscriptionLocked is addSubscription's unlocked core, for callers
+// that already hold bus's write lock, such as catchUpPass holding it across
+// both reading the journal and registration.
+func (bus *Bus) addSubscriptionLocked(t string, f interface{}, configure func(*subscriptionEntry)) (*Subscription, error) {
+	if sig, ok := bus.signatures[t]; ok {
+		if err := validateCallbackSignature(reflect.TypeOf(f), sig); err != nil {
+			return nil, fmt.Errorf("callback for topic %s does not match registered signature: %w", t, err)
+		}
+	}
+
+	entry := newSubscriptionEntry(reflect.ValueOf(f))
+	configure(entry)
+
+	if err := bus.topics.add(t, entry); err != nil {
+		return nil, err
+	}
+
+	return &Subscription{bus: bus, topic: t, id: entry.id}, nil
+}
+
+// setPendingLocked clears or sets the pending flag on the entry identified
+// by topic and id. The caller must hold bus's write lock. It is a no-op if
+// the entry is no longer registered.
+func (bus *Bus) setPendingLocked(topic string, id uint64, pending bool) {
+	node := bus.topics.node(topic)
+	if node == nil {
+		return
+	}
+
+	if i := node.byID(id); i >= 0 {
+		node.callbacks[i].pending = pending
+	}
+}
+
+// removeSubscriptionLocked is removeSubscription's unlocked core, for
+// callers that already hold bus's write lock.
+func (bus *Bus) removeSubscriptionLocked(topic string, id uint64) bool {
+	node := bus.topics.node(topic)
+	if node == nil {
+		return false
+	}
+
+	i := node.byID(id)
+	if i < 0 {
+		return false
+	}
+
+	node.callbacks = append(node.callbacks[:i], node.callbacks[i+1:]...)
+	bus.topics.pruneEmpty()
+
+	return true
+}
+
+func (bus *Bus) removeSubscription(topic string, id uint64) bool {
+	bus.Lock()
+	defer bus.Unlock()
+
+	return bus.removeSubscriptionLocked(topic, id)
+}
@@ -0,0 +1,93 @@
+package event
+
+import "testing"
+
+func TestAddOnceFiresOnlyOnce(t *testing.T) {
+	bus := newTestBus(t)
+
+	hits := 0
+	sub, err := bus.AddOnce("test", func() { hits++ })
+	if err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	bus.Fire("test")
+	bus.Fire("test")
+
+	if hits != 1 {
+		t.Errorf("Expected callback to fire once, fired %d times", hits)
+	}
+
+	if sub.Unsubscribe() {
+		t.Errorf("Expected Unsubscribe to report false after AddOnce already fired")
+	}
+}
+
+func TestAddWithFilterOnlyDispatchesMatching(t *testing.T) {
+	bus := newTestBus(t)
+
+	var got []int
+	_, err := bus.AddWithFilter("test", func(args ...interface{}) bool {
+		return args[0].(int) > 1
+	}, func(n int) { got = append(got, n) })
+	if err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	bus.Fire("test", 1)
+	bus.Fire("test", 2)
+	bus.Fire("test", 3)
+
+	if len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("Expected filtered dispatch of [2 3], got %v", got)
+	}
+}
+
+func TestAddWithTTLExpiresAndUnsubscribes(t *testing.T) {
+	bus := newTestBus(t)
+
+	hits := 0
+	sub, err := bus.AddWithTTL("test", 0, func() { hits++ })
+	if err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	bus.Fire("test")
+
+	if hits != 0 {
+		t.Errorf("Expected expired callback not to fire, fired %d times", hits)
+	}
+
+	if sub.Unsubscribe() {
+		t.Errorf("Expected Unsubscribe to report false after expiry already removed it")
+	}
+}
+
+func TestUnsubscribeRemovesOnlyThatSubscription(t *testing.T) {
+	bus := newTestBus(t)
+
+	hitsOne, hitsTwo := 0, 0
+	subOne, err := bus.Add("test", func() { hitsOne++ })
+	if err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+	if _, err := bus.Add("test", func() { hitsTwo++ }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	if !subOne.Unsubscribe() {
+		t.Errorf("Expected Unsubscribe to report true removing a live subscription")
+	}
+	if subOne.Unsubscribe() {
+		t.Errorf("Expected repeated Unsubscribe to report false")
+	}
+
+	bus.Fire("test")
+
+	if hitsOne != 0 {
+		t.Errorf("Expected unsubscribed callback not to fire, fired %d times", hitsOne)
+	}
+	if hitsTwo != 1 {
+		t.Errorf("Expected remaining callback to fire once, fired %d times", hitsTwo)
+	}
+}
@@ -0,0 +1,149 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+)
+
+// topicTrie indexes registered callbacks by dotted topic pattern, e.g.
+// "irc.privmsg.#channel". A pattern segment of "+" matches exactly one
+// concrete segment, and "#", only valid as the final segment, matches any
+// number of trailing segments. Matching a concrete topic against the trie
+// stays proportional to the topic's own depth rather than the number of
+// registered patterns.
+type topicTrie struct {
+	root *topicNode
+}
+
+type topicNode struct {
+	children  map[string]*topicNode
+	callbacks []*subscriptionEntry
+}
+
+func newTopicTrie() *topicTrie {
+	return &topicTrie{root: &topicNode{children: make(map[string]*topicNode)}}
+}
+
+func splitTopic(topic string) []string {
+	return strings.Split(topic, ".")
+}
+
+func validatePattern(segments []string) error {
+	for i, seg := range segments {
+		if seg == "" {
+			return fmt.Errorf("topic pattern %q has an empty segment", strings.Join(segments, "."))
+		}
+		if seg == "#" && i != len(segments)-1 {
+			return fmt.Errorf("topic pattern %q: '#' is only valid as the final segment", strings.Join(segments, "."))
+		}
+	}
+
+	return nil
+}
+
+// add registers entry under pattern, creating trie nodes as needed.
+func (tt *topicTrie) add(pattern string, entry *subscriptionEntry) error {
+	segments := splitTopic(pattern)
+	if err := validatePattern(segments); err != nil {
+		return err
+	}
+
+	node := tt.root
+	for _, seg := range segments {
+		child, ok := node.children[seg]
+		if !ok {
+			child = &topicNode{children: make(map[string]*topicNode)}
+			node.children[seg] = child
+		}
+		node = child
+	}
+
+	node.callbacks = append(node.callbacks, entry)
+
+	return nil
+}
+
+// node returns the node registered for the exact pattern, without wildcard
+// expansion, or nil if nothing was ever registered under it. It's used by
+// Delete and DeleteAll, which operate on a specific registration rather
+// than a concrete, fired topic.
+func (tt *topicTrie) node(pattern string) *topicNode {
+	node := tt.root
+	for _, seg := range splitTopic(pattern) {
+		child, ok := node.children[seg]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	return node
+}
+
+// byID returns the index of the callback registered under this exact node
+// with the given subscription id, or -1 if none matches.
+func (n *topicNode) byID(id uint64) int {
+	for i, e := range n.callbacks {
+		if e.id == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// match returns the callbacks of every pattern matching the concrete,
+// wildcard-free topic.
+func (tt *topicTrie) match(topic string) []*subscriptionEntry {
+	var matched []*subscriptionEntry
+	tt.root.match(splitTopic(topic), &matched)
+	return matched
+}
+
+func (n *topicNode) match(segments []string, out *[]*subscriptionEntry) {
+	if child, ok := n.children["#"]; ok {
+		*out = append(*out, child.callbacks...)
+	}
+
+	if len(segments) == 0 {
+		*out = append(*out, n.callbacks...)
+		return
+	}
+
+	if child, ok := n.children[segments[0]]; ok {
+		child.match(segments[1:], out)
+	}
+
+	if child, ok := n.children["+"]; ok {
+		child.match(segments[1:], out)
+	}
+}
+
+// walk visits every node in the trie, including those with no callbacks of
+// their own, for use by Purge.
+func (tt *topicTrie) walk(fn func(*topicNode)) {
+	tt.root.walk(fn)
+}
+
+func (n *topicNode) walk(fn func(*topicNode)) {
+	fn(n)
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
+// pruneEmpty removes descendant nodes left with no callbacks and no
+// children of their own, after a deletion.
+func (tt *topicTrie) pruneEmpty() {
+	tt.root.pruneEmpty()
+}
+
+func (n *topicNode) pruneEmpty() bool {
+	for seg, child := range n.children {
+		if child.pruneEmpty() {
+			delete(n.children, seg)
+		}
+	}
+
+	return len(n.callbacks) == 0 && len(n.children) == 0
+}
@@ -0,0 +1,91 @@
+package event
+
+import "testing"
+
+func TestAddRejectsHashNotLast(t *testing.T) {
+	bus := newTestBus(t)
+
+	_, err := bus.Add("irc.#.privmsg", testCallbackEmpty)
+	if err == nil {
+		t.Errorf("Did not get error adding pattern with '#' before the last segment")
+	}
+}
+
+func TestFirePlusWildcardMatchesOneSegment(t *testing.T) {
+	bus := newTestBus(t)
+
+	hits := 0
+	_, err := bus.Add("irc.+.privmsg", func() { hits++ })
+	if err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	bus.Fire("irc.channel1.privmsg")
+	bus.Fire("irc.channel2.privmsg")
+	bus.Fire("irc.channel1.other.privmsg")
+
+	if hits != 2 {
+		t.Errorf("Expected 2 matches, got %d", hits)
+	}
+}
+
+func TestFireHashWildcardMatchesTail(t *testing.T) {
+	bus := newTestBus(t)
+
+	hits := 0
+	_, err := bus.Add("admin.#", func() { hits++ })
+	if err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	bus.Fire("admin.kick")
+	bus.Fire("admin.kick.channel1")
+	bus.Fire("admin")
+	bus.Fire("other.kick")
+
+	if hits != 3 {
+		t.Errorf("Expected 3 matches, got %d", hits)
+	}
+}
+
+func TestFireMatchesMultiplePatterns(t *testing.T) {
+	bus := newTestBus(t)
+
+	var literal, plus, hash bool
+
+	if _, err := bus.Add("irc.privmsg", func() { literal = true }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+	if _, err := bus.Add("irc.+", func() { plus = true }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+	if _, err := bus.Add("irc.#", func() { hash = true }); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	bus.Fire("irc.privmsg")
+
+	if !literal || !plus || !hash {
+		t.Errorf("Expected all three patterns to match, got literal=%v plus=%v hash=%v", literal, plus, hash)
+	}
+}
+
+func TestDeleteExactPatternDoesNotAffectWildcards(t *testing.T) {
+	bus := newTestBus(t)
+
+	if _, err := bus.Add("irc.+", testCallbackEmpty); err != nil {
+		t.Fatalf("Encountered error adding callback: %+v", err)
+	}
+
+	ok, err := bus.Delete("irc.privmsg", testCallbackEmpty)
+	if ok {
+		t.Errorf("Deleted callback registered under a different pattern")
+	}
+	if err != nil {
+		t.Errorf("Encountered unexpected error: %+v", err)
+	}
+
+	if node := bus.topics.node("irc.+"); node == nil || len(node.callbacks) != 1 {
+		t.Errorf("Expected wildcard pattern's callback to remain untouched")
+	}
+}